@@ -0,0 +1,98 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+)
+
+// soapEnvelope is the wire shape used to unwrap a SOAP response body
+// regardless of which SOAP version produced it; both the 1.1 and 1.2
+// envelope namespaces marshal onto the same local names.
+type soapEnvelope struct {
+	XMLName xml.Name `xml:"Envelope"`
+	Body    soapBody `xml:"Body"`
+}
+
+type soapBody struct {
+	Content []byte `xml:",innerxml"`
+}
+
+// DecoderOptions configures a DefaultDecoder.
+type DecoderOptions struct {
+	// FaultDetail, if set, is the type a SOAP fault's <detail>/<Detail>
+	// element is unmarshaled into, exposed via SOAPFaultError.Detail.
+	// Pass the element type, not a pointer (e.g. reflect.TypeOf(MyDetail{})).
+	FaultDetail reflect.Type
+}
+
+// DefaultDecoder implements the SOAPDecoder interface
+type DefaultDecoder struct {
+	reader  io.Reader
+	options DecoderOptions
+}
+
+// NewDecoder creates a new SOAP decoder that reads from the specified reader
+func NewDecoder(r io.Reader) SOAPDecoder {
+	return NewDecoderWithOptions(r, DecoderOptions{})
+}
+
+// NewDecoderWithOptions creates a new SOAP decoder that reads from the
+// specified reader, unmarshaling fault details into options.FaultDetail
+// when a response carries a SOAP fault.
+func NewDecoderWithOptions(r io.Reader, options DecoderOptions) SOAPDecoder {
+	return &DefaultDecoder{reader: r, options: options}
+}
+
+// Decode reads a SOAP envelope from the underlying reader and unmarshals its
+// body content into v. If the body contains a SOAP fault, Decode leaves v
+// untouched and returns a SOAPFaultError instead.
+func (d *DefaultDecoder) Decode(v interface{}) error {
+	var env soapEnvelope
+	if err := xml.NewDecoder(d.reader).Decode(&env); err != nil {
+		return err
+	}
+
+	if fault, ok := d.decodeFault(env); ok {
+		return fault
+	}
+
+	return xml.Unmarshal(env.Body.Content, v)
+}
+
+// decodeFault reports whether the envelope body is a SOAP fault and, if so,
+// parses it using the envelope's own namespace to pick the 1.1 or 1.2 shape.
+func (d *DefaultDecoder) decodeFault(env soapEnvelope) (SOAPFaultError, bool) {
+	if !bytes.Contains(env.Body.Content, []byte("Fault")) {
+		return nil, false
+	}
+
+	if env.XMLName.Space == soap12Namespace {
+		var f Fault12
+		if err := xml.Unmarshal(env.Body.Content, &f); err != nil || f.Code.Value == "" {
+			return nil, false
+		}
+		d.resolveDetail(f.DetailElement)
+		return &f, true
+	}
+
+	var f Fault11
+	if err := xml.Unmarshal(env.Body.Content, &f); err != nil || f.Code == "" {
+		return nil, false
+	}
+	d.resolveDetail(f.DetailElement)
+	return &f, true
+}
+
+// resolveDetail unmarshals a fault's raw detail content into the
+// caller-registered FaultDetail type, if any.
+func (d *DefaultDecoder) resolveDetail(detail *FaultDetail) {
+	if detail == nil || d.options.FaultDetail == nil {
+		return
+	}
+	parsed := reflect.New(d.options.FaultDetail)
+	if err := xml.Unmarshal(detail.Content, parsed.Interface()); err == nil {
+		detail.Parsed = parsed.Interface()
+	}
+}
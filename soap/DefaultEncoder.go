@@ -1,136 +1,163 @@
 package soap
 
 import (
+	"encoding/xml"
 	"fmt"
 	"io"
-	"reflect"
-	"strings"
 )
 
-// SOAPEnvelopeStart represents the opening part of a SOAP envelope
-const SOAPEnvelopeStart = `<?xml version="1.0" encoding="utf-8"?>
-<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
-  <soap:Body>
-`
+const (
+	soap11Namespace   = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12Namespace   = "http://www.w3.org/2003/05/soap-envelope"
+	soap11ContentType = "text/xml; charset=\"utf-8\""
+	soap12ContentType = "application/soap+xml; charset=\"utf-8\""
+)
 
-// SOAPEnvelopeEnd represents the closing part of a SOAP envelope
-const SOAPEnvelopeEnd = `  </soap:Body>
-</soap:Envelope>`
+// EncoderOptions configures a DefaultEncoder.
+type EncoderOptions struct {
+	// Version selects the SOAP envelope version to emit. Defaults to
+	// SOAP11 when left as the zero value.
+	Version SOAPVersion
+	// EncodingStyle, if set, is rendered as the soap:encodingStyle
+	// attribute on the envelope.
+	EncodingStyle string
+	// Headers are encoded as children of <soap:Header> before the body.
+	// Each value is marshaled with encoding/xml, so it can be a struct
+	// with an XMLName field, a pointer, or anything else xml.Marshal
+	// accepts. Callers can also append to this list after construction
+	// via DefaultEncoder.AddHeader.
+	Headers []interface{}
+}
 
-// DefaultEncoder implements the SOAPEncoder interface
+// DefaultEncoder implements the SOAPEncoder interface on top of
+// encoding/xml, so values passed to Encode can be arbitrary structs:
+// nested types, slices, pointers, attributes (,attr), ,chardata, ,cdata,
+// ,innerxml, and namespaced element names via XMLName xml.Name all work
+// exactly as they do with xml.Marshal.
 type DefaultEncoder struct {
-	writer io.Writer
-	buffer []byte
+	writer  io.Writer
+	xmlEnc  *xml.Encoder
+	options EncoderOptions
+	headers []interface{}
+	started bool
 }
 
-// NewEncoder creates a new SOAP encoder that writes to the specified writer
+// NewEncoder creates a new SOAP 1.1 encoder that writes to the specified writer
 func NewEncoder(w io.Writer) SOAPEncoder {
+	return NewEncoderWithOptions(w, EncoderOptions{})
+}
+
+// NewEncoderWithOptions creates a new SOAP encoder that writes to the
+// specified writer using the given options, allowing callers to pick the
+// SOAP version, encoding style, and header content.
+func NewEncoderWithOptions(w io.Writer, options EncoderOptions) SOAPEncoder {
 	return &DefaultEncoder{
-		writer: w,
-		buffer: []byte{},
+		writer:  w,
+		xmlEnc:  xml.NewEncoder(w),
+		options: options,
+		headers: append([]interface{}{}, options.Headers...),
 	}
 }
 
-// Encode converts a Go struct to SOAP XML format and adds it to the buffer
-func (e *DefaultEncoder) Encode(v interface{}) error {
-	// Get the value and type of the interface
-	val := reflect.ValueOf(v)
-	if val.Kind() == reflect.Ptr {
-		val = val.Elem()
-	}
+// AddHeader registers a value to be serialized as a child of <soap:Header>,
+// before the body. v is marshaled with encoding/xml.
+func (e *DefaultEncoder) AddHeader(v interface{}) {
+	e.headers = append(e.headers, v)
+}
 
-	// Check if the struct has XMLName field
-	xmlName, namespace := getXMLNameAndNamespace(val)
-	if xmlName == "" {
-		return fmt.Errorf("struct must have XMLName field")
-	}
+// envelopeName returns the element name used for the soap:Envelope tag.
+//
+// This is deliberately a bare "soap:Envelope" Local with no Space: setting
+// Space instead (e.g. to the envelope namespace) makes encoding/xml emit it
+// as a default xmlns="..." declaration, which every descendant element
+// with an unqualified XMLName (including arbitrary body/header payloads)
+// would then silently inherit. Writing the literal prefix plus an explicit
+// xmlns:soap attribute keeps the envelope namespace off of everything else.
+func (e *DefaultEncoder) envelopeName() xml.Name {
+	return xml.Name{Local: "soap:Envelope"}
+}
 
-	// Start with the envelope
-	if len(e.buffer) == 0 {
-		e.buffer = append(e.buffer, []byte(SOAPEnvelopeStart)...)
+// start writes the opening <Envelope>, the <Header> block if any headers
+// were registered, and the opening <Body> tag. It is idempotent so that
+// repeated calls to Encode only open the envelope once.
+func (e *DefaultEncoder) start() error {
+	if e.started {
+		return nil
 	}
+	e.started = true
 
-	// Create the root element with namespace
-	rootStart := fmt.Sprintf("    <%s xmlns=\"%s\">", xmlName, namespace)
-	e.buffer = append(e.buffer, []byte(rootStart)...)
+	if _, err := io.WriteString(e.writer, xml.Header); err != nil {
+		return err
+	}
 
-	// Process all fields except XMLName
-	typ := val.Type()
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Field(i)
-		fieldType := typ.Field(i)
+	envStart := xml.StartElement{
+		Name: e.envelopeName(),
+		Attr: []xml.Attr{{
+			Name:  xml.Name{Local: "xmlns:soap"},
+			Value: e.options.Version.EnvelopeNamespace(),
+		}},
+	}
+	if e.options.EncodingStyle != "" {
+		envStart.Attr = append(envStart.Attr, xml.Attr{
+			Name:  xml.Name{Local: "soap:encodingStyle"},
+			Value: e.options.EncodingStyle,
+		})
+	}
+	if err := e.xmlEnc.EncodeToken(envStart); err != nil {
+		return fmt.Errorf("soap: writing envelope start: %w", err)
+	}
 
-		// Skip XMLName field
-		if fieldType.Name == "XMLName" {
-			continue
+	if len(e.headers) > 0 {
+		headerStart := xml.StartElement{Name: xml.Name{Local: "soap:Header"}}
+		if err := e.xmlEnc.EncodeToken(headerStart); err != nil {
+			return fmt.Errorf("soap: writing header start: %w", err)
 		}
-
-		// Get the XML tag name
-		xmlTag := fieldType.Tag.Get("xml")
-		parts := strings.Split(xmlTag, ",")
-		fieldName := parts[0]
-
-		// Skip if field is empty and omitempty is specified
-		if field.IsZero() && strings.Contains(xmlTag, "omitempty") {
-			continue
+		for _, h := range e.headers {
+			if err := e.xmlEnc.Encode(h); err != nil {
+				return fmt.Errorf("soap: encoding header: %w", err)
+			}
 		}
-
-		// Format the field value
-		var fieldValue string
-		switch field.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			fieldValue = fmt.Sprintf("%d", field.Int())
-		case reflect.String:
-			fieldValue = field.String()
-		default:
-			fieldValue = fmt.Sprintf("%v", field.Interface())
+		if err := e.xmlEnc.EncodeToken(headerStart.End()); err != nil {
+			return fmt.Errorf("soap: writing header end: %w", err)
 		}
-
-		// Add the field with empty namespace
-		fieldXML := fmt.Sprintf("\n      <%s xmlns=\"\">%s</%s>", fieldName, fieldValue, fieldName)
-		e.buffer = append(e.buffer, []byte(fieldXML)...)
 	}
 
-	// Close the root element
-	rootEnd := fmt.Sprintf("\n    </%s>", xmlName)
-	e.buffer = append(e.buffer, []byte(rootEnd)...)
+	if err := e.xmlEnc.EncodeToken(xml.StartElement{Name: xml.Name{Local: "soap:Body"}}); err != nil {
+		return fmt.Errorf("soap: writing body start: %w", err)
+	}
+	return nil
+}
 
+// Encode marshals v as a child element of <soap:Body>, opening the envelope
+// first if this is the first call since the last Flush.
+func (e *DefaultEncoder) Encode(v interface{}) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	if err := e.xmlEnc.Encode(v); err != nil {
+		return fmt.Errorf("soap: encoding body: %w", err)
+	}
 	return nil
 }
 
-// Flush writes the buffered XML to the writer and clears the buffer
+// Flush closes the body and envelope elements, writes everything to the
+// underlying writer, and resets the encoder so it can be reused for another
+// message.
 func (e *DefaultEncoder) Flush() error {
-	if len(e.buffer) > 0 {
-		// Add the envelope end
-		e.buffer = append(e.buffer, []byte("\n"+SOAPEnvelopeEnd)...)
-
-		// Write to the output
-		_, err := e.writer.Write(e.buffer)
-		if err != nil {
-			return err
-		}
+	if !e.started {
+		return nil
+	}
 
-		// Clear the buffer
-		e.buffer = []byte{}
+	if err := e.xmlEnc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "soap:Body"}}); err != nil {
+		return fmt.Errorf("soap: writing body end: %w", err)
+	}
+	if err := e.xmlEnc.EncodeToken(xml.EndElement{Name: e.envelopeName()}); err != nil {
+		return fmt.Errorf("soap: writing envelope end: %w", err)
+	}
+	if err := e.xmlEnc.Flush(); err != nil {
+		return err
 	}
 
+	e.started = false
 	return nil
 }
-
-// getXMLNameAndNamespace extracts the XML element name and namespace from the XMLName field
-func getXMLNameAndNamespace(val reflect.Value) (string, string) {
-	typ := val.Type()
-	for i := 0; i < val.NumField(); i++ {
-		field := typ.Field(i)
-		if field.Name == "XMLName" {
-			// Extract namespace and element name from the xml tag
-			xmlTag := field.Tag.Get("xml")
-			parts := strings.Split(xmlTag, " ")
-			if len(parts) == 2 {
-				return parts[1], parts[0]
-			}
-			return field.Name, ""
-		}
-	}
-	return "", ""
-}
@@ -0,0 +1,58 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+type item struct {
+	SKU   string `xml:"sku,attr"`
+	Label string `xml:",chardata"`
+}
+
+type order struct {
+	XMLName xml.Name `xml:"Order"`
+	ID      string   `xml:"Id"`
+	Items   []item   `xml:"Items>Item"`
+	Note    *string  `xml:"Note,omitempty"`
+}
+
+func TestDefaultEncoderRoundTrip(t *testing.T) {
+	req := &order{
+		ID: "42",
+		Items: []item{
+			{SKU: "A1", Label: "Widget"},
+			{SKU: "B2", Label: "Gadget"},
+		},
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	if err := enc.Encode(req); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	var got order
+	if err := NewDecoder(bytes.NewReader(buf.Bytes())).Decode(&got); err != nil {
+		t.Fatalf("Decode: %v\nenvelope:\n%s", err, buf.String())
+	}
+
+	if got.ID != req.ID {
+		t.Errorf("ID = %q, want %q", got.ID, req.ID)
+	}
+	if len(got.Items) != len(req.Items) {
+		t.Fatalf("got %d items, want %d", len(got.Items), len(req.Items))
+	}
+	for i := range req.Items {
+		if got.Items[i] != req.Items[i] {
+			t.Errorf("Items[%d] = %+v, want %+v", i, got.Items[i], req.Items[i])
+		}
+	}
+	if got.Note != nil {
+		t.Errorf("Note = %v, want nil (omitempty)", *got.Note)
+	}
+}
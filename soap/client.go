@@ -0,0 +1,214 @@
+package soap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// RetryPolicy controls how Client.CallContext retries a request after a
+// transport-level error (a SOAP fault or malformed response is never
+// retried, since retrying won't change the service's answer).
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// A zero value means 1 (no retries).
+	MaxAttempts int
+	// Backoff returns how long to wait before the given attempt (1-based,
+	// the attempt about to be retried). Defaults to no wait when nil.
+	Backoff func(attempt int) time.Duration
+}
+
+// Client performs SOAP requests over HTTP, streaming the request body
+// through a DefaultEncoder and decoding the response with a DefaultDecoder.
+type Client struct {
+	// URL is the endpoint the client sends requests to.
+	URL string
+	// HTTPClient is used to perform the underlying HTTP request, and owns
+	// connection pooling via its Transport. Defaults to http.DefaultClient
+	// when nil; callers that want pooling across many Clients should share
+	// one HTTPClient between them.
+	HTTPClient *http.Client
+	// Options controls the SOAP version and encoding style used to build
+	// requests.
+	Options EncoderOptions
+	// FaultDetail, if set, is the type a SOAP fault's detail element is
+	// unmarshaled into. See DecoderOptions.FaultDetail.
+	FaultDetail reflect.Type
+	// Retry controls retry/backoff behavior for transport-level errors.
+	// The zero value makes a single attempt.
+	Retry RetryPolicy
+	// OnRequest, if set, is called with the outgoing *http.Request before
+	// it is sent, e.g. for logging or signing.
+	OnRequest func(*http.Request) error
+	// OnResponse, if set, is called with the *http.Response before it is
+	// decoded, e.g. for logging.
+	OnResponse func(*http.Response) error
+}
+
+// NewClient creates a Client that talks to the given URL using SOAP 1.1 by
+// default.
+func NewClient(url string) *Client {
+	return &Client{URL: url}
+}
+
+// WithHeaders registers values to be emitted as children of <soap:Header> on
+// every request made with this client, such as the elements returned by
+// NewWSAddressingHeaders or a WSSecurityHeader. It returns c for chaining.
+func (c *Client) WithHeaders(headers ...interface{}) *Client {
+	c.Options.Headers = append(c.Options.Headers, headers...)
+	return c
+}
+
+// WithFaultDetail registers the type a SOAP fault's detail element should be
+// unmarshaled into for every call made with this client. Pass the element
+// type itself, not a pointer (e.g. reflect.TypeOf(MyFaultDetail{})). It
+// returns c for chaining.
+func (c *Client) WithFaultDetail(t reflect.Type) *Client {
+	c.FaultDetail = t
+	return c
+}
+
+// WithRetry sets the retry/backoff policy used for transport-level errors.
+// It returns c for chaining.
+func (c *Client) WithRetry(policy RetryPolicy) *Client {
+	c.Retry = policy
+	return c
+}
+
+// Call is equivalent to CallContext(context.Background(), action, request, response).
+func (c *Client) Call(action string, request, response interface{}) error {
+	return c.CallContext(context.Background(), action, request, response)
+}
+
+// CallContext encodes request, streaming it straight into the HTTP request
+// body without buffering the whole envelope in memory, sends it to the
+// client's URL with the correct Content-Type/SOAPAction for the configured
+// SOAP version, and decodes the response into response. It honors ctx
+// cancellation and retries transport-level errors per c.Retry.
+func (c *Client) CallContext(ctx context.Context, action string, request, response interface{}) error {
+	attempts := c.Retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if err := c.wait(ctx, attempt-1); err != nil {
+				return err
+			}
+		}
+
+		resp, err := c.do(ctx, action, request)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		err = c.decode(resp, response)
+		if err != nil {
+			if faultErr, ok := err.(SOAPFaultError); ok {
+				return faultErr
+			}
+			// A response was received and parsed (or failed to parse); the
+			// service has already answered, so retrying would not help.
+			return err
+		}
+		return nil
+	}
+	return lastErr
+}
+
+// do streams an encoded request to the client's URL and returns the HTTP
+// response, running OnRequest before the request is sent.
+func (c *Client) do(ctx context.Context, action string, request interface{}) (*http.Response, error) {
+	pr, pw := io.Pipe()
+
+	// Build and validate the request before starting the encoding
+	// goroutine below: if anything here fails, nothing will ever read
+	// from pr, so the goroutine must not be started yet or its write to
+	// pw would block forever.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, pr)
+	if err != nil {
+		pw.Close()
+		return nil, fmt.Errorf("soap: building request: %w", err)
+	}
+	req.Header.Set("Content-Type", c.Options.Version.ContentType(action))
+	if c.Options.Version == SOAP11 && action != "" {
+		req.Header.Set("SOAPAction", fmt.Sprintf("%q", action))
+	}
+
+	if c.OnRequest != nil {
+		if err := c.OnRequest(req); err != nil {
+			pw.Close()
+			return nil, fmt.Errorf("soap: request interceptor: %w", err)
+		}
+	}
+
+	go func() {
+		encoder := NewEncoderWithOptions(pw, c.Options)
+		err := encoder.Encode(request)
+		if err == nil {
+			err = encoder.Flush()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		// Unblock the encoding goroutine if the request was never (fully)
+		// read, e.g. because it failed before or while reading the body.
+		pr.CloseWithError(err)
+		return nil, fmt.Errorf("soap: performing request: %w", err)
+	}
+	return resp, nil
+}
+
+// decode runs OnResponse and decodes resp's body into response, closing the
+// body when done.
+func (c *Client) decode(resp *http.Response, response interface{}) error {
+	defer resp.Body.Close()
+
+	if c.OnResponse != nil {
+		if err := c.OnResponse(resp); err != nil {
+			return fmt.Errorf("soap: response interceptor: %w", err)
+		}
+	}
+
+	decoder := NewDecoderWithOptions(resp.Body, DecoderOptions{FaultDetail: c.FaultDetail})
+	if err := decoder.Decode(response); err != nil {
+		if faultErr, ok := err.(SOAPFaultError); ok {
+			return faultErr
+		}
+		return fmt.Errorf("soap: decoding response: %w", err)
+	}
+	return nil
+}
+
+// wait sleeps for the backoff duration before the given retry attempt,
+// returning early with ctx.Err() if ctx is done first.
+func (c *Client) wait(ctx context.Context, attempt int) error {
+	if c.Retry.Backoff == nil {
+		return nil
+	}
+	d := c.Retry.Backoff(attempt)
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
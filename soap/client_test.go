@@ -0,0 +1,91 @@
+package soap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestClientCallContextSuccess(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("SOAPAction"); got != `"DoFoo"` {
+			t.Errorf("SOAPAction header = %q, want %q", got, `"DoFoo"`)
+		}
+		w.Header().Set("Content-Type", soap11ContentType)
+		w.Write([]byte(`<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body><GetFoo><Id>9</Id></GetFoo></soap:Body>
+</soap:Envelope>`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	var resp getFooRequest
+	if err := client.Call("DoFoo", &getFooRequest{ID: "1"}, &resp); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if resp.ID != "9" {
+		t.Errorf("resp.ID = %q, want %q", resp.ID, "9")
+	}
+}
+
+type flakyRoundTripper struct {
+	failures int
+	attempts int
+}
+
+func (rt *flakyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.attempts++
+	if rt.attempts <= rt.failures {
+		return nil, errors.New("simulated transport failure")
+	}
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Type", soap11ContentType)
+	rec.WriteString(`<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body><GetFoo><Id>ok</Id></GetFoo></soap:Body>
+</soap:Envelope>`)
+	return rec.Result(), nil
+}
+
+func TestClientCallContextRetriesTransportErrors(t *testing.T) {
+	rt := &flakyRoundTripper{failures: 2}
+	client := NewClient("http://example.invalid/svc")
+	client.HTTPClient = &http.Client{Transport: rt}
+	client.Retry = RetryPolicy{MaxAttempts: 3}
+
+	var resp getFooRequest
+	if err := client.CallContext(context.Background(), "DoFoo", &getFooRequest{ID: "1"}, &resp); err != nil {
+		t.Fatalf("CallContext: %v", err)
+	}
+	if resp.ID != "ok" {
+		t.Errorf("resp.ID = %q, want %q", resp.ID, "ok")
+	}
+	if rt.attempts != 3 {
+		t.Errorf("attempts = %d, want 3", rt.attempts)
+	}
+}
+
+func TestClientCallContextDoesNotLeakGoroutineOnBuildFailure(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	client := NewClient("://not-a-valid-url")
+	for i := 0; i < 20; i++ {
+		var resp getFooRequest
+		if err := client.CallContext(context.Background(), "DoFoo", &getFooRequest{ID: "1"}, &resp); err == nil {
+			t.Fatal("expected an error building the request")
+		}
+	}
+
+	runtime.GC()
+	time.Sleep(50 * time.Millisecond)
+
+	after := runtime.NumGoroutine()
+	if after > before+5 {
+		t.Errorf("goroutine count grew from %d to %d after 20 failed calls; encoding goroutine may be leaking", before, after)
+	}
+}
@@ -0,0 +1,102 @@
+package soap
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// SOAPFaultError is the error type returned by a SOAPDecoder when a
+// response body contains a <Fault> element. Fault11 and Fault12 both
+// implement it.
+type SOAPFaultError interface {
+	error
+	// FaultCode returns the fault's code (faultcode for 1.1, Code/Value
+	// for 1.2).
+	FaultCode() string
+	// FaultString returns the human-readable fault message (faultstring
+	// for 1.1, Reason/Text for 1.2).
+	FaultString() string
+	// Detail returns the value registered via Client.WithFaultDetail /
+	// DecoderOptions.FaultDetail once unmarshaled, or nil if none was
+	// registered or the detail element was absent.
+	Detail() interface{}
+}
+
+// FaultDetail holds the raw contents of a fault's detail element. Register
+// a concrete type with Client.WithFaultDetail to have it unmarshaled into
+// Parsed.
+type FaultDetail struct {
+	Content []byte      `xml:",innerxml"`
+	Parsed  interface{} `xml:"-"`
+}
+
+// Fault11 is a SOAP 1.1 fault, as carried in <soap:Fault><faultcode>...
+type Fault11 struct {
+	XMLName       xml.Name     `xml:"Fault"`
+	Code          string       `xml:"faultcode"`
+	String        string       `xml:"faultstring"`
+	Actor         string       `xml:"faultactor,omitempty"`
+	DetailElement *FaultDetail `xml:"detail,omitempty"`
+}
+
+func (f *Fault11) Error() string {
+	return fmt.Sprintf("soap fault %s: %s", f.Code, f.String)
+}
+
+// FaultCode returns the SOAP 1.1 faultcode.
+func (f *Fault11) FaultCode() string { return f.Code }
+
+// FaultString returns the SOAP 1.1 faultstring.
+func (f *Fault11) FaultString() string { return f.String }
+
+// Detail returns the unmarshaled fault detail, or nil if none was registered.
+func (f *Fault11) Detail() interface{} {
+	if f.DetailElement == nil {
+		return nil
+	}
+	return f.DetailElement.Parsed
+}
+
+// Fault12 is a SOAP 1.2 fault, as carried in <soap:Fault><soap:Code>...
+type Fault12 struct {
+	XMLName       xml.Name      `xml:"Fault"`
+	Code          Fault12Code   `xml:"Code"`
+	Reason        Fault12Reason `xml:"Reason"`
+	Node          string        `xml:"Node,omitempty"`
+	Role          string        `xml:"Role,omitempty"`
+	DetailElement *FaultDetail  `xml:"Detail,omitempty"`
+}
+
+// Fault12Code is the SOAP 1.2 fault code, optionally refined by a subcode.
+type Fault12Code struct {
+	Value   string          `xml:"Value"`
+	Subcode *Fault12Subcode `xml:"Subcode,omitempty"`
+}
+
+// Fault12Subcode refines a Fault12Code.
+type Fault12Subcode struct {
+	Value string `xml:"Value"`
+}
+
+// Fault12Reason carries the human-readable fault text for one language.
+type Fault12Reason struct {
+	Text string `xml:"Text"`
+}
+
+func (f *Fault12) Error() string {
+	return fmt.Sprintf("soap fault %s: %s", f.Code.Value, f.Reason.Text)
+}
+
+// FaultCode returns the SOAP 1.2 Code/Value.
+func (f *Fault12) FaultCode() string { return f.Code.Value }
+
+// FaultString returns the SOAP 1.2 Reason/Text.
+func (f *Fault12) FaultString() string { return f.Reason.Text }
+
+// Detail returns the unmarshaled fault detail, or nil if none was registered.
+func (f *Fault12) Detail() interface{} {
+	if f.DetailElement == nil {
+		return nil
+	}
+	return f.DetailElement.Parsed
+}
@@ -0,0 +1,96 @@
+package soap
+
+import (
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type myFaultDetail struct {
+	XMLName xml.Name `xml:"FaultInfo"`
+	Reason  string   `xml:"Reason"`
+}
+
+func TestDecodeSOAP11Fault(t *testing.T) {
+	body := `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <soap:Fault>
+      <faultcode>soap:Client</faultcode>
+      <faultstring>bad request</faultstring>
+      <detail><FaultInfo><Reason>missing field</Reason></FaultInfo></detail>
+    </soap:Fault>
+  </soap:Body>
+</soap:Envelope>`
+
+	dec := NewDecoderWithOptions(strings.NewReader(body), DecoderOptions{FaultDetail: reflect.TypeOf(myFaultDetail{})})
+	var out getFooRequest
+	err := dec.Decode(&out)
+	if err == nil {
+		t.Fatal("expected a fault error, got nil")
+	}
+
+	var faultErr SOAPFaultError
+	if !errors.As(err, &faultErr) {
+		t.Fatalf("error %v does not implement SOAPFaultError", err)
+	}
+	if faultErr.FaultString() != "bad request" {
+		t.Errorf("FaultString() = %q, want %q", faultErr.FaultString(), "bad request")
+	}
+	detail, ok := faultErr.Detail().(*myFaultDetail)
+	if !ok {
+		t.Fatalf("Detail() = %#v, want *myFaultDetail", faultErr.Detail())
+	}
+	if detail.Reason != "missing field" {
+		t.Errorf("detail.Reason = %q, want %q", detail.Reason, "missing field")
+	}
+}
+
+func TestDecodeSOAP12Fault(t *testing.T) {
+	body := `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope">
+  <soap:Body>
+    <soap:Fault>
+      <soap:Code><soap:Value>soap:Sender</soap:Value></soap:Code>
+      <soap:Reason><soap:Text>invalid input</soap:Text></soap:Reason>
+    </soap:Fault>
+  </soap:Body>
+</soap:Envelope>`
+
+	dec := NewDecoder(strings.NewReader(body))
+	var out getFooRequest
+	err := dec.Decode(&out)
+	if err == nil {
+		t.Fatal("expected a fault error, got nil")
+	}
+
+	fault12, ok := err.(*Fault12)
+	if !ok {
+		t.Fatalf("error is %T, want *Fault12", err)
+	}
+	if fault12.FaultCode() != "soap:Sender" {
+		t.Errorf("FaultCode() = %q, want %q", fault12.FaultCode(), "soap:Sender")
+	}
+	if fault12.FaultString() != "invalid input" {
+		t.Errorf("FaultString() = %q, want %q", fault12.FaultString(), "invalid input")
+	}
+}
+
+func TestDecodeNonFaultBody(t *testing.T) {
+	body := `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <GetFoo><Id>7</Id></GetFoo>
+  </soap:Body>
+</soap:Envelope>`
+
+	var out getFooRequest
+	if err := NewDecoder(strings.NewReader(body)).Decode(&out); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if out.ID != "7" {
+		t.Errorf("ID = %q, want %q", out.ID, "7")
+	}
+}
@@ -0,0 +1,68 @@
+package soap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWSAddressingHeadersEncode(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	for _, h := range NewWSAddressingHeaders("http://example.com/svc", "DoFoo", "urn:uuid:1", "") {
+		enc.AddHeader(h)
+	}
+	if err := enc.Encode(&getFooRequest{ID: "1"}); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"<soap:Header>",
+		"<To", "http://example.com/svc", "</To>",
+		"<Action", "DoFoo", "</Action>",
+		"<MessageID", "urn:uuid:1", "</MessageID>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "ReplyTo") {
+		t.Errorf("empty replyTo should be omitted, got:\n%s", out)
+	}
+	// Each wsa element must stand on its own, not be wrapped in a bogus
+	// extra <Header> element nested inside soap:Header.
+	if strings.Count(out, "<soap:Header>") != 1 || strings.Count(out, "Header xmlns") > 0 {
+		t.Errorf("expected a single soap:Header wrapper with no nested Header element:\n%s", out)
+	}
+}
+
+func TestUsernameTokenEncode(t *testing.T) {
+	token, err := NewUsernameToken("alice", "s3cr3t", false)
+	if err != nil {
+		t.Fatalf("NewUsernameToken: %v", err)
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.AddHeader(token)
+	if err := enc.Encode(&getFooRequest{ID: "1"}); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"<Security", "<UsernameToken>", "<Username>alice</Username>",
+		`Type="` + passwordTypeText + `"`, "s3cr3t", "</UsernameToken>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"mime"
+	"mime/multipart"
+	"testing"
+)
+
+type mtomAttachmentList struct {
+	XMLName xml.Name        `xml:"UploadFiles"`
+	Direct  MTOMAttachment  `xml:"Direct"`
+	Files   []mtomNamedFile `xml:"Files>File"`
+}
+
+type mtomNamedFile struct {
+	Name string         `xml:"Name"`
+	Data MTOMAttachment `xml:"Data"`
+}
+
+func TestMTOMEncodeDecodeRoundTrip(t *testing.T) {
+	req := &mtomAttachmentList{
+		Direct: MTOMAttachment{ContentType: "application/octet-stream", Data: []byte("direct-bytes")},
+		Files: []mtomNamedFile{
+			{Name: "a.bin", Data: MTOMAttachment{ContentType: "application/octet-stream", Data: []byte("file-a")}},
+			{Name: "b.bin", Data: MTOMAttachment{ContentType: "application/octet-stream", Data: []byte("file-b")}},
+		},
+	}
+
+	var buf bytes.Buffer
+	enc := NewMTOMEncoder(&buf)
+	if err := enc.Encode(req); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	var got mtomAttachmentList
+	dec := NewMTOMDecoder(bytes.NewReader(buf.Bytes()), enc.ContentType())
+	if err := dec.Decode(&got); err != nil {
+		t.Fatalf("Decode: %v\nmessage:\n%s", err, buf.String())
+	}
+
+	if string(got.Direct.Data) != "direct-bytes" {
+		t.Errorf("Direct.Data = %q, want %q", got.Direct.Data, "direct-bytes")
+	}
+	if len(got.Files) != 2 {
+		t.Fatalf("got %d files, want 2", len(got.Files))
+	}
+	if string(got.Files[0].Data.Data) != "file-a" {
+		t.Errorf("Files[0].Data.Data = %q, want %q", got.Files[0].Data.Data, "file-a")
+	}
+	if string(got.Files[1].Data.Data) != "file-b" {
+		t.Errorf("Files[1].Data.Data = %q, want %q", got.Files[1].Data.Data, "file-b")
+	}
+}
+
+// TestMTOMEncoderRootPartContentType asserts the root MIME part's own
+// Content-Type, not just that this package's own (lenient) decoder accepts
+// it: per XOP/MTOM the root part must be application/xop+xml with a "type"
+// parameter carrying the original SOAP content type, the reverse of what a
+// naive concatenation of the two produces.
+func TestMTOMEncoderRootPartContentType(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewMTOMEncoder(&buf)
+	if err := enc.Encode(&mtomAttachmentList{Direct: MTOMAttachment{ContentType: "application/octet-stream", Data: []byte("x")}}); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	_, params, err := mime.ParseMediaType(enc.ContentType())
+	if err != nil {
+		t.Fatalf("parsing envelope content-type: %v", err)
+	}
+	mr := multipart.NewReader(bytes.NewReader(buf.Bytes()), params["boundary"])
+	part, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("reading root part: %v", err)
+	}
+
+	mediaType, rootParams, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("parsing root part content-type %q: %v", part.Header.Get("Content-Type"), err)
+	}
+	if mediaType != "application/xop+xml" {
+		t.Errorf("root part media type = %q, want %q", mediaType, "application/xop+xml")
+	}
+	wantType, _, _ := mime.ParseMediaType(soap11ContentType)
+	if rootParams["type"] != wantType {
+		t.Errorf(`root part "type" param = %q, want %q`, rootParams["type"], wantType)
+	}
+}
+
+// TestMTOMDecodeRejectsMessageWithNoXOPRootPart ensures a malformed message
+// with no application/xop+xml part is rejected instead of silently treating
+// whichever part arrives first as the envelope.
+func TestMTOMDecodeRejectsMessageWithNoXOPRootPart(t *testing.T) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.SetBoundary("b1")
+	part, err := mw.CreatePart(map[string][]string{"Content-Type": {"application/octet-stream"}})
+	if err != nil {
+		t.Fatalf("CreatePart: %v", err)
+	}
+	part.Write([]byte("not a soap envelope"))
+	mw.Close()
+
+	dec := NewMTOMDecoder(&buf, `multipart/related; type="application/xop+xml"; boundary=b1`)
+	var got mtomAttachmentList
+	if err := dec.Decode(&got); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+}
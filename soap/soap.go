@@ -0,0 +1,67 @@
+package soap
+
+// SOAPEncoder encodes Go values into a SOAP envelope and writes them to an
+// underlying writer.
+type SOAPEncoder interface {
+	Encode(v interface{}) error
+	Flush() error
+	// AddHeader registers a value to be serialized as a child of
+	// <soap:Header>, before the body written by Encode.
+	AddHeader(v interface{})
+}
+
+// SOAPDecoder decodes a SOAP envelope read from an underlying reader into a
+// Go value.
+type SOAPDecoder interface {
+	Decode(v interface{}) error
+}
+
+// SOAPVersion identifies which SOAP envelope/transport conventions an
+// encoder or client should use.
+type SOAPVersion int
+
+const (
+	// SOAP11 is the SOAP 1.1 envelope namespace and transport conventions
+	// (SOAPAction header, text/xml content type).
+	SOAP11 SOAPVersion = iota
+	// SOAP12 is the SOAP 1.2 envelope namespace and transport conventions
+	// (action carried as an application/soap+xml content-type parameter).
+	SOAP12
+)
+
+// String returns a human readable name for the SOAP version.
+func (v SOAPVersion) String() string {
+	switch v {
+	case SOAP12:
+		return "1.2"
+	default:
+		return "1.1"
+	}
+}
+
+// EnvelopeNamespace returns the SOAP envelope XML namespace for this version.
+func (v SOAPVersion) EnvelopeNamespace() string {
+	switch v {
+	case SOAP12:
+		return soap12Namespace
+	default:
+		return soap11Namespace
+	}
+}
+
+// ContentType returns the HTTP Content-Type used when sending a request
+// encoded with this SOAP version. For SOAP 1.2, action is embedded as the
+// "action" content-type parameter; for SOAP 1.1 it is carried separately in
+// the SOAPAction header.
+func (v SOAPVersion) ContentType(action string) string {
+	switch v {
+	case SOAP12:
+		ct := soap12ContentType
+		if action != "" {
+			ct += `; action="` + action + `"`
+		}
+		return ct
+	default:
+		return soap11ContentType
+	}
+}
@@ -0,0 +1,57 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestSOAPVersionContentType(t *testing.T) {
+	if got := SOAP11.ContentType("DoFoo"); got != soap11ContentType {
+		t.Errorf("SOAP11.ContentType(action) = %q, want %q (action is carried in SOAPAction, not Content-Type)", got, soap11ContentType)
+	}
+
+	got := SOAP12.ContentType("DoFoo")
+	want := soap12ContentType + `; action="DoFoo"`
+	if got != want {
+		t.Errorf("SOAP12.ContentType(%q) = %q, want %q", "DoFoo", got, want)
+	}
+
+	if got := SOAP12.ContentType(""); got != soap12ContentType {
+		t.Errorf("SOAP12.ContentType(\"\") = %q, want %q", got, soap12ContentType)
+	}
+}
+
+func TestSOAPVersionEnvelopeNamespace(t *testing.T) {
+	if got := SOAP11.EnvelopeNamespace(); got != soap11Namespace {
+		t.Errorf("SOAP11.EnvelopeNamespace() = %q, want %q", got, soap11Namespace)
+	}
+	if got := SOAP12.EnvelopeNamespace(); got != soap12Namespace {
+		t.Errorf("SOAP12.EnvelopeNamespace() = %q, want %q", got, soap12Namespace)
+	}
+}
+
+type getFooRequest struct {
+	XMLName xml.Name `xml:"GetFoo"`
+	ID      string   `xml:"Id"`
+}
+
+func TestEncoderUsesVersionNamespace(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoderWithOptions(&buf, EncoderOptions{Version: SOAP12})
+	if err := enc.Encode(&getFooRequest{ID: "1"}); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `xmlns:soap="`+soap12Namespace+`"`) {
+		t.Errorf("output missing SOAP 1.2 envelope namespace declaration:\n%s", out)
+	}
+	if strings.Contains(out, soap11Namespace) {
+		t.Errorf("output unexpectedly contains SOAP 1.1 namespace:\n%s", out)
+	}
+}